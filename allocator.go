@@ -0,0 +1,415 @@
+package main
+
+// Allocator picks where a new allocation of size KB for pid should land
+// within pages, and is responsible for updating its own bookkeeping when a
+// previous allocation is freed. Implementations are stateful: NextFit
+// remembers its cursor and the buddy allocator remembers its free lists, so
+// an allocator should only ever be driven against the pages slice it was
+// last used with.
+type Allocator interface {
+	// Name is the short label shown in the strategy panel and status bar.
+	Name() string
+	// Allocate finds room for size KB belonging to pid and marks the
+	// covering pages as used. It reports the starting page index and
+	// whether the allocation succeeded.
+	Allocate(pages []page, size int, pid string) (start int, ok bool)
+	// Free releases the pageCount pages starting at start, which were
+	// previously returned by Allocate.
+	Free(pages []page, start int, pageCount int)
+	// Steps returns the search steps taken during the most recent Allocate
+	// call (pages examined by the scanning strategies, free-list orders
+	// probed by the buddy allocator), used to report average search cost
+	// per strategy.
+	Steps() int
+	// InternalFragmentation returns the number of pages allocated but not
+	// requested during the most recent Allocate call (0 if that call failed,
+	// and always 0 for allocators that hand out exactly the requested page
+	// count).
+	InternalFragmentation() int
+}
+
+// pagesNeeded converts a size in KB into a whole number of pages, rounding up.
+func pagesNeeded(pages []page, size int) int {
+	if len(pages) == 0 {
+		return 0
+	}
+	n := size / pageSizeKB
+	if size%pageSizeKB != 0 {
+		n++
+	}
+	if n < 1 {
+		n = 1
+	}
+	return n
+}
+
+// pageSizeKB mirrors model.stats.pageSize; allocators are free functions of
+// the pages slice so they need their own copy of the constant.
+const pageSizeKB = 4
+
+// firstFitAllocator returns the first contiguous run of free pages that is
+// large enough, scanning from the start of the pool every time.
+type firstFitAllocator struct {
+	lastSteps int
+}
+
+func (a *firstFitAllocator) Name() string { return "First-Fit" }
+
+func (a *firstFitAllocator) Allocate(pages []page, size int, pid string) (int, bool) {
+	required := pagesNeeded(pages, size)
+	start, steps := scanForRun(pages, 0, required)
+	a.lastSteps = steps
+	if start == -1 {
+		return -1, false
+	}
+	markUsed(pages, start, required, pid)
+	return start, true
+}
+
+func (a *firstFitAllocator) Free(pages []page, start int, pageCount int) {
+	markFree(pages, start, pageCount)
+}
+
+func (a *firstFitAllocator) Steps() int                 { return a.lastSteps }
+func (a *firstFitAllocator) InternalFragmentation() int { return 0 }
+
+// nextFitAllocator behaves like First-Fit but resumes scanning from where
+// the previous search left off instead of restarting at page 0.
+type nextFitAllocator struct {
+	cursor    int
+	lastSteps int
+}
+
+func (a *nextFitAllocator) Name() string { return "Next-Fit" }
+
+func (a *nextFitAllocator) Allocate(pages []page, size int, pid string) (int, bool) {
+	required := pagesNeeded(pages, size)
+	start, steps := scanForRun(pages, a.cursor, required)
+	if start == -1 && a.cursor != 0 {
+		// Wrap around once from the beginning in case the run we need
+		// straddles the point we started at.
+		var wrapSteps int
+		start, wrapSteps = scanForRun(pages, 0, required)
+		steps += wrapSteps
+	}
+	a.lastSteps = steps
+	if start == -1 {
+		return -1, false
+	}
+	markUsed(pages, start, required, pid)
+	a.cursor = (start + required) % len(pages)
+	return start, true
+}
+
+func (a *nextFitAllocator) Free(pages []page, start int, pageCount int) {
+	markFree(pages, start, pageCount)
+}
+
+func (a *nextFitAllocator) Steps() int                 { return a.lastSteps }
+func (a *nextFitAllocator) InternalFragmentation() int { return 0 }
+
+// bestFitAllocator scans every free run and picks the smallest one that
+// still fits the request, minimizing leftover space in the chosen run.
+type bestFitAllocator struct {
+	lastSteps int
+}
+
+func (a *bestFitAllocator) Name() string { return "Best-Fit" }
+
+func (a *bestFitAllocator) Allocate(pages []page, size int, pid string) (int, bool) {
+	required := pagesNeeded(pages, size)
+	runs, steps := freeRuns(pages)
+	a.lastSteps = steps
+
+	bestStart, bestLen := -1, -1
+	for _, r := range runs {
+		if r.length < required {
+			continue
+		}
+		if bestLen == -1 || r.length < bestLen {
+			bestStart, bestLen = r.start, r.length
+		}
+	}
+	if bestStart == -1 {
+		return -1, false
+	}
+	markUsed(pages, bestStart, required, pid)
+	return bestStart, true
+}
+
+func (a *bestFitAllocator) Free(pages []page, start int, pageCount int) {
+	markFree(pages, start, pageCount)
+}
+
+func (a *bestFitAllocator) Steps() int                 { return a.lastSteps }
+func (a *bestFitAllocator) InternalFragmentation() int { return 0 }
+
+// worstFitAllocator scans every free run and picks the largest one,
+// leaving behind the biggest possible leftover run for future requests.
+type worstFitAllocator struct {
+	lastSteps int
+}
+
+func (a *worstFitAllocator) Name() string { return "Worst-Fit" }
+
+func (a *worstFitAllocator) Allocate(pages []page, size int, pid string) (int, bool) {
+	required := pagesNeeded(pages, size)
+	runs, steps := freeRuns(pages)
+	a.lastSteps = steps
+
+	worstStart, worstLen := -1, -1
+	for _, r := range runs {
+		if r.length < required {
+			continue
+		}
+		if r.length > worstLen {
+			worstStart, worstLen = r.start, r.length
+		}
+	}
+	if worstStart == -1 {
+		return -1, false
+	}
+	markUsed(pages, worstStart, required, pid)
+	return worstStart, true
+}
+
+func (a *worstFitAllocator) Free(pages []page, start int, pageCount int) {
+	markFree(pages, start, pageCount)
+}
+
+func (a *worstFitAllocator) Steps() int                 { return a.lastSteps }
+func (a *worstFitAllocator) InternalFragmentation() int { return 0 }
+
+// run describes a contiguous stretch of free pages.
+type run struct {
+	start  int
+	length int
+}
+
+// scanForRun walks pages starting at from (wrapping is the caller's job)
+// looking for the first run of at least required free pages. It returns the
+// run's start index (or -1) and the number of pages it had to examine.
+func scanForRun(pages []page, from int, required int) (int, int) {
+	steps := 0
+	start := -1
+	count := 0
+	for i := from; i < len(pages); i++ {
+		steps++
+		if !pages[i].used {
+			if start == -1 {
+				start = i
+			}
+			count++
+			if count >= required {
+				return start, steps
+			}
+		} else {
+			start = -1
+			count = 0
+		}
+	}
+	return -1, steps
+}
+
+// freeRuns collects every maximal run of free pages in the pool.
+func freeRuns(pages []page) ([]run, int) {
+	var runs []run
+	steps := 0
+	start := -1
+	for i := range pages {
+		steps++
+		if !pages[i].used {
+			if start == -1 {
+				start = i
+			}
+		} else if start != -1 {
+			runs = append(runs, run{start: start, length: i - start})
+			start = -1
+		}
+	}
+	if start != -1 {
+		runs = append(runs, run{start: start, length: len(pages) - start})
+	}
+	return runs, steps
+}
+
+func markUsed(pages []page, start int, count int, pid string) {
+	for i := start; i < start+count && i < len(pages); i++ {
+		pages[i] = page{used: true, processID: pid}
+	}
+}
+
+func markFree(pages []page, start int, count int) {
+	for i := start; i < start+count && i < len(pages); i++ {
+		pages[i] = page{}
+	}
+}
+
+// buddyAllocator implements the binary buddy system over a pool whose size
+// is a power of two (the tracker's 64-page pool). Free blocks are kept in
+// per-order free lists; allocating rounds a request up to the nearest
+// power-of-two block size and splits larger blocks as needed, while freeing
+// walks back up merging with the buddy (found by XORing the block's index
+// with its size) whenever that buddy is itself free and the same order.
+type buddyAllocator struct {
+	maxOrder         int
+	freeLists        [][]int // freeLists[order] holds start indices of free blocks of size 2^order pages
+	lastSteps        int
+	lastInternalFrag int
+}
+
+func newBuddyAllocator(poolSize int) *buddyAllocator {
+	order := 0
+	for (1 << order) < poolSize {
+		order++
+	}
+	b := &buddyAllocator{
+		maxOrder:  order,
+		freeLists: make([][]int, order+1),
+	}
+	b.freeLists[order] = []int{0}
+	return b
+}
+
+func (a *buddyAllocator) Name() string { return "Buddy System" }
+
+func (a *buddyAllocator) Allocate(pages []page, size int, pid string) (int, bool) {
+	required := pagesNeeded(pages, size)
+	order := 0
+	for (1 << order) < required {
+		order++
+	}
+	steps := 0
+
+	// Find the smallest free block at order or above, splitting down.
+	src := -1
+	for o := order; o <= a.maxOrder; o++ {
+		steps++
+		if len(a.freeLists[o]) > 0 {
+			src = o
+			break
+		}
+	}
+	a.lastSteps = steps
+	if src == -1 {
+		a.lastInternalFrag = 0
+		return -1, false
+	}
+
+	// Pop the block and split it down to the target order.
+	n := len(a.freeLists[src])
+	start := a.freeLists[src][n-1]
+	a.freeLists[src] = a.freeLists[src][:n-1]
+	for o := src; o > order; o-- {
+		half := start + (1 << (o - 1))
+		a.freeLists[o-1] = append(a.freeLists[o-1], half)
+	}
+
+	blockPages := 1 << order
+	markUsed(pages, start, blockPages, pid)
+	a.lastInternalFrag = blockPages - required
+	return start, true
+}
+
+func (a *buddyAllocator) Free(pages []page, start int, pageCount int) {
+	order := 0
+	for (1 << order) < pageCount {
+		order++
+	}
+	markFree(pages, start, 1<<order)
+
+	index := start
+	for order < a.maxOrder {
+		buddy := index ^ (1 << order)
+		if !freeListHas(a.freeLists[order], buddy) {
+			break
+		}
+		a.freeLists[order] = removeValue(a.freeLists[order], buddy)
+		if buddy < index {
+			index = buddy
+		}
+		order++
+	}
+	a.freeLists[order] = append(a.freeLists[order], index)
+}
+
+func (a *buddyAllocator) Steps() int                 { return a.lastSteps }
+func (a *buddyAllocator) InternalFragmentation() int { return a.lastInternalFrag }
+
+// freeListHas reports whether value is present in list without mutating it.
+func freeListHas(list []int, value int) bool {
+	for _, v := range list {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// removeValue returns list with the first occurrence of value removed.
+func removeValue(list []int, value int) []int {
+	for i, v := range list {
+		if v == value {
+			return append(list[:i], list[i+1:]...)
+		}
+	}
+	return list
+}
+
+// defaultAllocators returns one instance of every supported strategy, in the
+// order they're bound to the '1'-'5' keys.
+func defaultAllocators(poolSize int) []Allocator {
+	return []Allocator{
+		&firstFitAllocator{},
+		&bestFitAllocator{},
+		&worstFitAllocator{},
+		&nextFitAllocator{},
+		newBuddyAllocator(poolSize),
+	}
+}
+
+// strategyStats accumulates running totals for one allocator so the
+// strategy panel can show success/failure counts and averages.
+type strategyStats struct {
+	successes            int
+	failures             int
+	totalSearchSteps     int
+	totalFragmentation   float64
+	fragmentationSamples int
+	totalInternalFrag    int
+}
+
+func (s *strategyStats) recordSuccess(searchSteps int, fragmentationRate float64, internalFrag int) {
+	s.successes++
+	s.totalSearchSteps += searchSteps
+	s.totalFragmentation += fragmentationRate
+	s.fragmentationSamples++
+	s.totalInternalFrag += internalFrag
+}
+
+func (s *strategyStats) recordFailure(searchSteps int) {
+	s.failures++
+	s.totalSearchSteps += searchSteps
+}
+
+func (s *strategyStats) averageSearchSteps() float64 {
+	total := s.successes + s.failures
+	if total == 0 {
+		return 0
+	}
+	return float64(s.totalSearchSteps) / float64(total)
+}
+
+func (s *strategyStats) averageFragmentation() float64 {
+	if s.fragmentationSamples == 0 {
+		return 0
+	}
+	return s.totalFragmentation / float64(s.fragmentationSamples)
+}
+
+func (s *strategyStats) averageInternalFragmentation() float64 {
+	if s.successes == 0 {
+		return 0
+	}
+	return float64(s.totalInternalFrag) / float64(s.successes)
+}
@@ -0,0 +1,152 @@
+package main
+
+// compactionFrames is how many redraw ticks a compaction move spends
+// flashing before it actually lands, so the TUI shows pages sliding rather
+// than snapping into place.
+const compactionFrames = 3
+
+// pageMove describes a single page relocating from one slot to another as
+// part of an in-flight compaction.
+type pageMove struct {
+	from, to int
+}
+
+// compact relocates every allocated page toward the low end of the pool,
+// squeezing out external fragmentation. The move is staged rather than
+// applied immediately: affected pages are marked migrating and the actual
+// data shuffle happens once advanceCompaction has let them flash for a few
+// frames.
+//
+// The buddy allocator keeps its free lists keyed to fixed, power-of-two
+// aligned block positions, so relocating pages out from under it would
+// desync that bookkeeping; compaction is only offered for the
+// contiguous-scan strategies.
+func (m *model) compact() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if len(m.pendingMoves) > 0 || len(m.segments) == 0 {
+		return
+	}
+	if _, isBuddy := m.activeAllocator().(*buddyAllocator); isBuddy {
+		return
+	}
+
+	target := make([]int, len(m.pages))
+	next := 0
+	for i, p := range m.pages {
+		if p.used {
+			target[i] = next
+			next++
+		} else {
+			target[i] = -1
+		}
+	}
+
+	var moves []pageMove
+	for i, t := range target {
+		if t != -1 && t != i {
+			moves = append(moves, pageMove{from: i, to: t})
+			m.pages[i].migrating = true
+		}
+	}
+	if len(moves) == 0 {
+		return
+	}
+
+	newSegmentStarts := make([]int, len(m.segments))
+	for i, s := range m.segments {
+		newSegmentStarts[i] = target[s.start]
+	}
+
+	m.pendingMoves = moves
+	m.pendingSegmentStarts = newSegmentStarts
+	m.compactionFrame = 0
+}
+
+// displaySlot is one rendering position for renderPages: either a settled
+// page or a page that's mid-migration and should be drawn at an
+// interpolated position rather than at its resting index.
+type displaySlot struct {
+	used      bool
+	migrating bool
+	processID string
+}
+
+// pageDisplaySlots computes which page glyph belongs in each of the pool's
+// rendering slots for the current redraw frame. Settled pages sit at their
+// real index; pages with an in-flight compaction move are placed at a slot
+// interpolated between their origin and destination based on
+// compactionFrame, so renderPages can show them sliding across several
+// frames instead of snapping straight to the destination.
+func (m *model) pageDisplaySlots() []displaySlot {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	slots := make([]displaySlot, len(m.pages))
+	occupied := make([]bool, len(m.pages))
+
+	for i, p := range m.pages {
+		if p.used && !p.migrating {
+			slots[i] = displaySlot{used: true, processID: p.processID}
+			occupied[i] = true
+		}
+	}
+
+	progress := float64(m.compactionFrame) / float64(compactionFrames)
+	for _, mv := range m.pendingMoves {
+		pos := mv.from + int(float64(mv.to-mv.from)*progress)
+		if pos < 0 {
+			pos = 0
+		}
+		if pos >= len(slots) {
+			pos = len(slots) - 1
+		}
+		if occupied[pos] {
+			// Another page already settled here; fall back to the origin
+			// slot rather than overwrite it.
+			pos = mv.from
+		}
+		slots[pos] = displaySlot{used: true, migrating: true, processID: m.pages[mv.from].processID}
+		occupied[pos] = true
+	}
+	return slots
+}
+
+// advanceCompaction steps an in-flight compaction forward by one redraw
+// frame, committing the page and segment moves once they've flashed for
+// compactionFrames ticks.
+func (m *model) advanceCompaction() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if len(m.pendingMoves) == 0 {
+		return
+	}
+	m.compactionFrame++
+	if m.compactionFrame < compactionFrames {
+		return
+	}
+
+	newPages := make([]page, len(m.pages))
+	for _, mv := range m.pendingMoves {
+		p := m.pages[mv.from]
+		p.migrating = false
+		newPages[mv.to] = p
+	}
+	for i, p := range m.pages {
+		if p.used && !p.migrating {
+			newPages[i] = p
+		}
+	}
+	m.pages = newPages
+
+	for i := range m.segments {
+		m.segments[i].start = m.pendingSegmentStarts[i]
+	}
+
+	m.compactedPages += len(m.pendingMoves)
+	m.pendingMoves = nil
+	m.pendingSegmentStarts = nil
+	m.compactionFrame = 0
+}
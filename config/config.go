@@ -0,0 +1,132 @@
+// Package config loads memtracker's user-facing settings: the active
+// colorscheme, the proportions of the main grid layout, and which panels
+// are shown. Settings come from a YAML file (by default
+// ~/.config/memtracker/config.yaml) with CLI flags able to override the
+// colorscheme and panel visibility on top of whatever the file says.
+package config
+
+import (
+	"embed"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+//go:embed colorschemes/*.yaml
+var bundledColorschemes embed.FS
+
+// Colorscheme holds the eight named colors memtracker paints its panels
+// with. Values are anything tcell.GetColor accepts: a color name
+// ("lime") or a hex string ("#00ff00").
+type Colorscheme struct {
+	Primary    string `yaml:"primary"`
+	Secondary  string `yaml:"secondary"`
+	Accent     string `yaml:"accent"`
+	Warning    string `yaml:"warning"`
+	Error      string `yaml:"error"`
+	Background string `yaml:"background"`
+	Text       string `yaml:"text"`
+	GrayText   string `yaml:"gray_text"`
+}
+
+// Layout controls the row and column proportions passed to the main
+// tview.Grid, using the same convention as tview.Grid.SetRows/SetColumns
+// (0 means "share remaining space").
+type Layout struct {
+	Rows    []int `yaml:"rows"`
+	Columns []int `yaml:"columns"`
+}
+
+// Panels toggles which top-level panels are placed in the layout. A disabled
+// panel is omitted from its grid entirely, so the remaining panels reflow
+// to fill the freed space.
+type Panels struct {
+	ShowSystemMemory  bool `yaml:"show_system_memory"`
+	ShowMemoryMetrics bool `yaml:"show_memory_metrics"`
+	ShowOperations    bool `yaml:"show_operations"`
+	ShowStrategy      bool `yaml:"show_strategy"`
+	ShowPaging        bool `yaml:"show_paging"`
+	ShowSegmentation  bool `yaml:"show_segmentation"`
+}
+
+// Config is the full set of user-facing settings.
+type Config struct {
+	Colorscheme Colorscheme `yaml:"colorscheme"`
+	Layout      Layout      `yaml:"layout"`
+	Panels      Panels      `yaml:"panels"`
+}
+
+// Default returns the settings memtracker ships with: the bundled
+// "default" colorscheme, the original grid proportions, and every panel
+// enabled.
+func Default() *Config {
+	cs, err := LoadColorscheme("default")
+	if err != nil {
+		// The default colorscheme is embedded in the binary, so this can
+		// only happen if it was removed from colorschemes/.
+		panic(fmt.Sprintf("config: bundled default colorscheme missing: %v", err))
+	}
+	return &Config{
+		Colorscheme: *cs,
+		Layout: Layout{
+			Rows:    []int{3, 1, 10, 1, 3, 1, 0},
+			Columns: []int{0, 0},
+		},
+		Panels: Panels{
+			ShowSystemMemory:  true,
+			ShowMemoryMetrics: true,
+			ShowOperations:    true,
+			ShowStrategy:      true,
+			ShowPaging:        true,
+			ShowSegmentation:  true,
+		},
+	}
+}
+
+// DefaultPath returns ~/.config/memtracker/config.yaml, or "" if the
+// user's home directory can't be determined.
+func DefaultPath() string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(home, ".config", "memtracker", "config.yaml")
+}
+
+// Load reads a YAML config file at path and overlays it onto Default().
+// A missing file is not an error: it just means "use the defaults".
+func Load(path string) (*Config, error) {
+	cfg := Default()
+	if path == "" {
+		return cfg, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return cfg, nil
+		}
+		return nil, fmt.Errorf("config: reading %s: %w", path, err)
+	}
+	if err := yaml.Unmarshal(data, cfg); err != nil {
+		return nil, fmt.Errorf("config: parsing %s: %w", path, err)
+	}
+	return cfg, nil
+}
+
+// LoadColorscheme looks up a colorscheme bundled in colorschemes/ by name
+// (case-insensitive), e.g. "solarized" or "monokai".
+func LoadColorscheme(name string) (*Colorscheme, error) {
+	data, err := bundledColorschemes.ReadFile("colorschemes/" + strings.ToLower(name) + ".yaml")
+	if err != nil {
+		return nil, fmt.Errorf("config: unknown colorscheme %q", name)
+	}
+	var cs Colorscheme
+	if err := yaml.Unmarshal(data, &cs); err != nil {
+		return nil, fmt.Errorf("config: parsing colorscheme %q: %w", name, err)
+	}
+	return &cs, nil
+}
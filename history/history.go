@@ -0,0 +1,62 @@
+// Package history keeps a fixed-size backlog of metric samples so the TUI
+// can plot recent trends instead of only the current instant.
+package history
+
+import "time"
+
+// Sample is one point in time: a set of named metric values captured
+// together.
+type Sample struct {
+	Timestamp time.Time
+	Metrics   map[string]float64
+}
+
+// Ring is a fixed-capacity ring buffer of Samples. Once full, adding a new
+// sample overwrites the oldest one.
+type Ring struct {
+	samples []Sample
+	start   int
+	count   int
+}
+
+// NewRing creates a Ring that retains at most capacity samples.
+func NewRing(capacity int) *Ring {
+	if capacity < 1 {
+		capacity = 1
+	}
+	return &Ring{samples: make([]Sample, capacity)}
+}
+
+// Add appends a sample, evicting the oldest one once the ring is full.
+func (r *Ring) Add(s Sample) {
+	idx := (r.start + r.count) % len(r.samples)
+	r.samples[idx] = s
+	if r.count < len(r.samples) {
+		r.count++
+	} else {
+		r.start = (r.start + 1) % len(r.samples)
+	}
+}
+
+// Len returns the number of samples currently retained.
+func (r *Ring) Len() int { return r.count }
+
+// Capacity returns the maximum number of samples the ring can retain.
+func (r *Ring) Capacity() int { return len(r.samples) }
+
+// Recent returns up to the n most recent samples, oldest first. n is
+// clamped to Len().
+func (r *Ring) Recent(n int) []Sample {
+	if n > r.count {
+		n = r.count
+	}
+	if n < 0 {
+		n = 0
+	}
+	out := make([]Sample, n)
+	for i := 0; i < n; i++ {
+		idx := (r.start + r.count - n + i) % len(r.samples)
+		out[i] = r.samples[idx]
+	}
+	return out
+}
@@ -1,30 +1,57 @@
 package main
 
 import (
+	"encoding/json"
 	"fmt"
 	"log"
 	"math/rand"
+	"os"
+	"path/filepath"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/gdamore/tcell/v2"
 	"github.com/rivo/tview"
 	"github.com/shirou/gopsutil/mem"
+	"github.com/spf13/cobra"
+
+	"github.com/prudh-vi/memory-allocation-tracker/config"
+	"github.com/prudh-vi/memory-allocation-tracker/history"
+	"github.com/prudh-vi/memory-allocation-tracker/workload"
 )
 
 // Data structures for memory representation
 type page struct {
 	used      bool
 	processID string
+	migrating bool // true while a compaction move is sliding this page to a new slot
 }
 
 type segment struct {
-	size      int
-	processID string
+	size       int
+	processID  string
+	start      int
+	pageCount  int
+	lastAccess time.Time // used by the LRU replacement policy
+	referenced bool      // used by the clock replacement policy
 }
 
 // Model represents the application state
 type model struct {
+	// mu guards pages, segments, the compaction fields derived from them,
+	// stats.fragmentationRate/totalAllocations/totalDeallocations, the swap
+	// counters, and historyPaused/historyWindow: all state that's mutated
+	// and read from three different goroutines (the tcell event-capture
+	// goroutine driving key presses, the 500ms render ticker, and the
+	// trace-replay goroutine started for --trace). Lock at the entry
+	// points (allocate, deallocateMemory/ByPID, selectAllocator,
+	// compact/advanceCompaction, updateStats/tickAnimations, the render*
+	// functions that read any of the above, zoomHistory, the ' ' key
+	// handler, writeSnapshot); internal helpers they call (tryAllocate,
+	// deallocateAt, evictUntilFit, selectVictim*) assume the caller already
+	// holds mu.
+	mu       sync.Mutex
 	pages    []page
 	segments []segment
 	stats    struct {
@@ -39,7 +66,40 @@ type model struct {
 		fragmentationRate  float64
 		peakMemoryUsage    float64
 	}
-	app *tview.Application
+	app    *tview.Application
+	config *config.Config
+
+	allocators       []Allocator
+	currentAllocator int
+	allocatorStats   map[string]*strategyStats
+
+	history        *history.Ring
+	historyWindow  int
+	historyPaused  bool
+	sampleInterval time.Duration
+
+	uiPages                *tview.Pages
+	overlay                string // "" | "help" | "confirm"
+	pendingDeallocate      bool
+	pendingDeallocateIndex int
+	pendingDeallocateTimer *time.Timer
+
+	traceActive  bool
+	tracePaused  bool
+	traceControl chan struct{}
+
+	pendingMoves         []pageMove
+	pendingSegmentStarts []int
+	compactionFrame      int
+	compactedPages       int
+	animTick             int
+
+	replacementPolicy string
+	clockHand         int
+	swapOutCount      int
+	swapInCount       int
+	swapInWindowCount int
+	swapInsPerSecond  float64
 }
 
 // Color scheme for the UI
@@ -55,24 +115,83 @@ var (
 	colorGrayText  = tcell.ColorGray
 )
 
+// applyColorscheme overrides the color globals above with the values from
+// cs, so every panel built after this call picks up the new palette.
+func applyColorscheme(cs config.Colorscheme) {
+	colorPrimary = colorFromName(cs.Primary, colorPrimary)
+	colorSecondary = colorFromName(cs.Secondary, colorSecondary)
+	colorAccent = colorFromName(cs.Accent, colorAccent)
+	colorWarning = colorFromName(cs.Warning, colorWarning)
+	colorError = colorFromName(cs.Error, colorError)
+	colorBg = colorFromName(cs.Background, colorBg)
+	colorText = colorFromName(cs.Text, colorText)
+	colorGrayText = colorFromName(cs.GrayText, colorGrayText)
+}
+
+// colorFromName resolves a color name or hex string via tcell, falling
+// back to fallback when name is empty.
+func colorFromName(name string, fallback tcell.Color) tcell.Color {
+	if name == "" {
+		return fallback
+	}
+	return tcell.GetColor(name)
+}
+
+// Metric keys stored in each history.Sample.
+const (
+	metricPageUsage     = "page_usage"
+	metricFragmentation = "fragmentation_rate"
+	metricSystemUsage   = "system_usage"
+)
+
+// defaultHistoryWindow is how many samples the history panel shows before
+// the user zooms with '+'/'-'.
+const defaultHistoryWindow = 60
+
 // Initialize the model
-func initialModel() *model {
+func initialModel(cfg *config.Config, historySize int, sampleInterval time.Duration, replacementPolicy string) *model {
 	pageCount := 64 // Increased number of memory pages to display
+	allocators := defaultAllocators(pageCount)
+
+	window := defaultHistoryWindow
+	if historySize < window {
+		window = historySize
+	}
+
 	m := &model{
-		pages:    make([]page, pageCount),
-		segments: []segment{},
-		app:      tview.NewApplication(),
+		pages:                  make([]page, pageCount),
+		segments:               []segment{},
+		app:                    tview.NewApplication(),
+		config:                 cfg,
+		allocators:             allocators,
+		allocatorStats:         make(map[string]*strategyStats, len(allocators)),
+		history:                history.NewRing(historySize),
+		historyWindow:          window,
+		sampleInterval:         sampleInterval,
+		replacementPolicy:      replacementPolicy,
+		pendingDeallocateIndex: -1,
 	}
 	m.stats.pageSize = 4 // 4KB page size
+	for _, a := range allocators {
+		m.allocatorStats[a.Name()] = &strategyStats{}
+	}
 	return m
 }
 
+// activeAllocator returns the strategy currently selected via the '1'-'5'
+// keybindings.
+func (m *model) activeAllocator() Allocator {
+	return m.allocators[m.currentAllocator]
+}
+
 // Create the main layout
-func (m *model) createLayout() *tview.Grid {
+func (m *model) createLayout() *tview.Pages {
+	panels := m.config.Panels
+
 	// Create the main grid layout
 	grid := tview.NewGrid().
-		SetRows(3, 1, 10, 1, 3, 1, 0).
-		SetColumns(0, 0).
+		SetRows(intsOrDefault(m.config.Layout.Rows, 3, 1, 10, 1, 3, 1, 0)...).
+		SetColumns(intsOrDefault(m.config.Layout.Columns, 0, 0)...).
 		SetBorders(false)
 
 	// Create the title bar with Matrix-style animation
@@ -95,7 +214,6 @@ func (m *model) createLayout() *tview.Grid {
 	// Create the memory stats panel
 	statsPanel := tview.NewGrid().
 		SetRows(0).
-		SetColumns(0, 0, 0).
 		SetBorders(false)
 
 	// System memory stats
@@ -137,9 +255,37 @@ func (m *model) createLayout() *tview.Grid {
 		SetTitleAlign(tview.AlignCenter).
 		SetBackgroundColor(colorBg)
 
-	statsPanel.AddItem(sysMemBox, 0, 0, 1, 1, 0, 0, false)
-	statsPanel.AddItem(memMetricsBox, 0, 1, 1, 1, 0, 0, false)
-	statsPanel.AddItem(opsBox, 0, 2, 1, 1, 0, 0, false)
+	// Allocation strategy stats
+	strategyBox := tview.NewTextView().
+		SetDynamicColors(true).
+		SetChangedFunc(func() {
+			m.app.Draw()
+		})
+	strategyBox.SetBorder(true).
+		SetBorderColor(colorSecondary).
+		SetTitle(" STRATEGY ").
+		SetTitleColor(colorAccent).
+		SetTitleAlign(tview.AlignCenter).
+		SetBackgroundColor(colorBg)
+
+	statsBoxes := []struct {
+		show bool
+		box  *tview.TextView
+	}{
+		{panels.ShowSystemMemory, sysMemBox},
+		{panels.ShowMemoryMetrics, memMetricsBox},
+		{panels.ShowOperations, opsBox},
+		{panels.ShowStrategy, strategyBox},
+	}
+	statsCols := make([]int, 0, len(statsBoxes))
+	for _, b := range statsBoxes {
+		if !b.show {
+			continue
+		}
+		statsPanel.AddItem(b.box, 0, len(statsCols), 1, 1, 0, 0, false)
+		statsCols = append(statsCols, 0)
+	}
+	statsPanel.SetColumns(statsCols...)
 
 	// Create the memory bar
 	memBar := tview.NewTextView().
@@ -154,10 +300,29 @@ func (m *model) createLayout() *tview.Grid {
 		SetTitleAlign(tview.AlignCenter).
 		SetBackgroundColor(colorBg)
 
+	// History sparklines
+	historyBox := tview.NewTextView().
+		SetDynamicColors(true).
+		SetChangedFunc(func() {
+			m.app.Draw()
+		})
+	historyBox.SetBorder(true).
+		SetBorderColor(colorSecondary).
+		SetTitle(" HISTORY ").
+		SetTitleColor(colorAccent).
+		SetTitleAlign(tview.AlignCenter).
+		SetBackgroundColor(colorBg)
+
+	memRow := tview.NewGrid().
+		SetRows(0).
+		SetColumns(0, 0).
+		SetBorders(false)
+	memRow.AddItem(memBar, 0, 0, 1, 1, 0, 0, false)
+	memRow.AddItem(historyBox, 0, 1, 1, 1, 0, 0, false)
+
 	// Create the memory visualization panel
 	memVisPanel := tview.NewGrid().
 		SetRows(0).
-		SetColumns(0, 0).
 		SetBorders(false)
 
 	// Paging visualization
@@ -186,15 +351,29 @@ func (m *model) createLayout() *tview.Grid {
 		SetTitleAlign(tview.AlignCenter).
 		SetBackgroundColor(colorBg)
 
-	memVisPanel.AddItem(pagingBox, 0, 0, 1, 1, 0, 0, false)
-	memVisPanel.AddItem(segmentationBox, 0, 1, 1, 1, 0, 0, false)
+	memVisBoxes := []struct {
+		show bool
+		box  *tview.TextView
+	}{
+		{panels.ShowPaging, pagingBox},
+		{panels.ShowSegmentation, segmentationBox},
+	}
+	memVisCols := make([]int, 0, len(memVisBoxes))
+	for _, b := range memVisBoxes {
+		if !b.show {
+			continue
+		}
+		memVisPanel.AddItem(b.box, 0, len(memVisCols), 1, 1, 0, 0, false)
+		memVisCols = append(memVisCols, 0)
+	}
+	memVisPanel.SetColumns(memVisCols...)
 
 	// Add all components to the main grid
 	grid.AddItem(titleBar, 0, 0, 1, 2, 0, 0, false)
 	grid.AddItem(tview.NewTextView().SetText(""), 1, 0, 1, 2, 0, 0, false) // Spacer
 	grid.AddItem(statsPanel, 2, 0, 1, 2, 0, 0, false)
 	grid.AddItem(tview.NewTextView().SetText(""), 3, 0, 1, 2, 0, 0, false) // Spacer
-	grid.AddItem(memBar, 4, 0, 1, 2, 0, 0, false)
+	grid.AddItem(memRow, 4, 0, 1, 2, 0, 0, false)
 	grid.AddItem(tview.NewTextView().SetText(""), 5, 0, 1, 2, 0, 0, false) // Spacer
 	grid.AddItem(memVisPanel, 6, 0, 1, 2, 0, 0, false)
 
@@ -202,36 +381,223 @@ func (m *model) createLayout() *tview.Grid {
 	go func() {
 		for {
 			m.updateStats()
+			m.tickAnimations()
 			sysMemBox.SetText(m.renderSystemMemory())
 			memMetricsBox.SetText(m.renderMemoryMetrics())
 			opsBox.SetText(m.renderOperations())
+			strategyBox.SetText(m.renderStrategyStats())
 			memBar.SetText(m.renderMemoryBar())
+			historyBox.SetText(m.renderHistory())
 			pagingBox.SetText(m.renderPages())
 			segmentationBox.SetText(m.renderSegments())
 			statusBar.SetText(m.renderStatusBar())
-			time.Sleep(500 * time.Millisecond)
+			time.Sleep(m.sampleInterval)
 		}
 	}()
 
-	// Set up key bindings
+	// Set up key bindings. Keys are handled as a small state machine so that
+	// 'd' requires two presses within a second to actually deallocate, and
+	// '?'/Esc toggle the help and confirmation overlays.
 	m.app.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
 		switch event.Key() {
-		case tcell.KeyEscape, tcell.KeyCtrlC:
+		case tcell.KeyEscape:
+			switch m.overlay {
+			case "help":
+				m.hideHelp()
+			case "confirm":
+				m.cancelDeallocateConfirm()
+			default:
+				m.app.Stop()
+			}
+		case tcell.KeyCtrlC:
 			m.app.Stop()
 		case tcell.KeyRune:
+			if m.overlay == "help" {
+				if event.Rune() == '?' {
+					m.hideHelp()
+				}
+				return event
+			}
+			if m.overlay == "confirm" {
+				// While the dd-confirm dialog is up, only the second 'd'
+				// (handled here) may act; every other key is swallowed so
+				// an allocation, compaction, or strategy/pool reset can't
+				// slip in between the two 'd' presses and invalidate the
+				// segment the dialog named.
+				if event.Rune() == 'd' && m.pendingDeallocate {
+					m.confirmDeallocate()
+				}
+				return event
+			}
 			switch event.Rune() {
 			case 'q':
 				m.app.Stop()
+			case '?':
+				m.showHelp()
 			case 'a':
 				m.allocateMemory()
 			case 'd':
-				m.deallocateMemory()
+				m.armDeallocateConfirm()
+			case 'c':
+				m.compact()
+			case '1', '2', '3', '4', '5':
+				m.selectAllocator(int(event.Rune() - '1'))
+			case '+':
+				m.zoomHistory(10)
+			case '-':
+				m.zoomHistory(-10)
+			case ' ':
+				m.mu.Lock()
+				m.historyPaused = !m.historyPaused
+				m.mu.Unlock()
+			case 'p':
+				if m.traceActive {
+					m.tracePaused = true
+				}
+			case 'r':
+				if m.traceActive {
+					m.tracePaused = false
+					m.traceWake()
+				}
+			case 'n':
+				if m.traceActive {
+					m.traceWake()
+				}
 			}
 		}
 		return event
 	})
 
-	return grid
+	helpModal := m.buildHelpModal()
+
+	m.uiPages = tview.NewPages().
+		AddPage("main", grid, true, true).
+		AddPage("help", helpModal, true, false)
+
+	return m.uiPages
+}
+
+// buildHelpModal creates the '?' overlay listing every keybinding, grouped
+// by category.
+func (m *model) buildHelpModal() *tview.Modal {
+	modal := tview.NewModal().
+		SetText(
+			"[yellow::b]NAVIGATION[white::-]\n" +
+				"q / Ctrl+C   quit\n" +
+				"Esc          close this help / cancel a pending dd\n\n" +
+				"[yellow::b]ALLOCATION[white::-]\n" +
+				"a            allocate memory\n" +
+				"d d          deallocate (press d twice within 1s to confirm)\n" +
+				"1-5          switch allocation strategy\n" +
+				"c            compact memory (relocate pages to the low end)\n\n" +
+				"[yellow::b]VIEW[white::-]\n" +
+				"+ / -        zoom the history window\n" +
+				"space        pause/resume sampling\n" +
+				"?            toggle this help\n\n" +
+				"[yellow::b]TRACE PLAYBACK (--trace)[white::-]\n" +
+				"p            pause trace playback\n" +
+				"n            single-step one trace command\n" +
+				"r            resume trace playback",
+		).
+		AddButtons([]string{"Close"}).
+		SetDoneFunc(func(buttonIndex int, buttonLabel string) {
+			m.hideHelp()
+		})
+	modal.SetBackgroundColor(colorBg)
+	return modal
+}
+
+// showHelp raises the help overlay, dismissing any pending deallocation
+// confirmation first.
+func (m *model) showHelp() {
+	if m.overlay == "confirm" {
+		m.cancelDeallocateConfirm()
+	}
+	m.uiPages.ShowPage("help")
+	m.overlay = "help"
+}
+
+// hideHelp lowers the help overlay.
+func (m *model) hideHelp() {
+	m.uiPages.HidePage("help")
+	m.overlay = ""
+}
+
+// armDeallocateConfirm shows a confirmation modal naming the segment that
+// would be freed and starts a 1s window for the second 'd' to land. If the
+// window lapses without a confirming keypress, the dialog is dismissed.
+func (m *model) armDeallocateConfirm() {
+	m.mu.Lock()
+	if len(m.segments) == 0 {
+		m.mu.Unlock()
+		return
+	}
+	index := len(m.segments) - 1
+	target := m.segments[index]
+	m.mu.Unlock()
+	m.pendingDeallocateIndex = index
+
+	modal := tview.NewModal().
+		SetText(fmt.Sprintf(
+			"Free %dKB segment owned by %s?\nPress d again within 1s to confirm.",
+			target.size, target.processID,
+		)).
+		AddButtons([]string{"Cancel"}).
+		SetDoneFunc(func(buttonIndex int, buttonLabel string) {
+			m.cancelDeallocateConfirm()
+		})
+	modal.SetBackgroundColor(colorBg)
+
+	if m.uiPages.HasPage("confirm") {
+		m.uiPages.RemovePage("confirm")
+	}
+	m.uiPages.AddPage("confirm", modal, true, true)
+	m.overlay = "confirm"
+	m.pendingDeallocate = true
+
+	if m.pendingDeallocateTimer != nil {
+		m.pendingDeallocateTimer.Stop()
+	}
+	m.pendingDeallocateTimer = time.AfterFunc(time.Second, func() {
+		m.app.QueueUpdateDraw(func() {
+			m.cancelDeallocateConfirm()
+		})
+	})
+}
+
+// confirmDeallocate frees the exact segment armDeallocateConfirm captured
+// and dismisses the dialog. It frees by that captured index rather than
+// re-reading the current last segment, since background keys are gated
+// while the dialog is up but the index is still the one source of truth
+// for "which segment did the dialog actually name". Refused while a
+// compaction is in flight; see deallocateMemory's doc comment. A
+// compaction can only be armed before the 'd' that opened this dialog
+// (not while it's up, since the overlay gating blocks 'c'), so this just
+// means the deallocation is dropped rather than desyncing the compaction's
+// staged segment indices.
+func (m *model) confirmDeallocate() {
+	m.mu.Lock()
+	if len(m.pendingMoves) == 0 && m.pendingDeallocateIndex >= 0 && m.pendingDeallocateIndex < len(m.segments) {
+		m.deallocateAt(m.pendingDeallocateIndex)
+	}
+	m.mu.Unlock()
+	m.cancelDeallocateConfirm()
+}
+
+// cancelDeallocateConfirm dismisses the deallocation confirmation without
+// freeing anything, stopping the pending auto-cancel timer if it's still
+// running.
+func (m *model) cancelDeallocateConfirm() {
+	m.pendingDeallocate = false
+	m.pendingDeallocateIndex = -1
+	m.overlay = ""
+	if m.pendingDeallocateTimer != nil {
+		m.pendingDeallocateTimer.Stop()
+		m.pendingDeallocateTimer = nil
+	}
+	if m.uiPages.HasPage("confirm") {
+		m.uiPages.RemovePage("confirm")
+	}
 }
 
 // Matrix-style title rendering
@@ -254,6 +620,7 @@ func (m *model) updateStats() {
 	// Update current time
 	m.stats.currentTime = time.Now()
 
+	m.mu.Lock()
 	// Calculate memory usage for pages
 	usedPages := 0
 	for _, p := range m.pages {
@@ -274,7 +641,11 @@ func (m *model) updateStats() {
 		}
 		fragmentation = float64(gaps) / float64(len(m.segments)) * 100
 	}
+	// Assigned while still holding mu: tryAllocate reads fragmentationRate
+	// under the same lock to seed a new segment's fragmentation sample, and
+	// this write must not land outside that critical section.
 	m.stats.fragmentationRate = fragmentation
+	m.mu.Unlock()
 
 	// Update peak memory usage
 	if memoryUsage > m.stats.peakMemoryUsage {
@@ -288,6 +659,20 @@ func (m *model) updateStats() {
 		m.stats.usedMemory = v.Used / 1024 / 1024
 		m.stats.freeMemory = v.Free / 1024 / 1024
 	}
+
+	m.mu.Lock()
+	paused := m.historyPaused
+	m.mu.Unlock()
+	if !paused {
+		m.history.Add(history.Sample{
+			Timestamp: m.stats.currentTime,
+			Metrics: map[string]float64{
+				metricPageUsage:     memoryUsage,
+				metricFragmentation: m.stats.fragmentationRate,
+				metricSystemUsage:   m.stats.memoryUsage,
+			},
+		})
+	}
 }
 
 // Render system memory stats
@@ -306,6 +691,7 @@ func (m *model) renderSystemMemory() string {
 
 // Render memory metrics
 func (m *model) renderMemoryMetrics() string {
+	m.mu.Lock()
 	// Calculate memory usage for pages
 	usedPages := 0
 	for _, p := range m.pages {
@@ -313,8 +699,11 @@ func (m *model) renderMemoryMetrics() string {
 			usedPages++
 		}
 	}
+	pageCount := len(m.pages)
+	fragmentationRate := m.stats.fragmentationRate
+	m.mu.Unlock()
 	// Use this variable in the return statement instead of calculating again
-	memoryUsage := float64(usedPages) / float64(len(m.pages)) * 100
+	memoryUsage := float64(usedPages) / float64(pageCount) * 100
 
 	return fmt.Sprintf(
 		"\n[yellow]Page Size:[white] %d KB\n"+
@@ -322,15 +711,19 @@ func (m *model) renderMemoryMetrics() string {
 			"[yellow]Peak Usage:[white] %.1f%%\n"+
 			"[yellow]Free Pages:[white] %d",
 		m.stats.pageSize,
-		m.stats.fragmentationRate,
+		fragmentationRate,
 		memoryUsage, // Use the calculated value here instead of recalculating
-		len(m.pages)-usedPages,
+		pageCount-usedPages,
 	)
 }
 
 // In the renderSegments function, fix the undefined variable p
 func (m *model) renderSegments() string {
-	if len(m.segments) == 0 {
+	m.mu.Lock()
+	segments := append([]segment(nil), m.segments...)
+	m.mu.Unlock()
+
+	if len(segments) == 0 {
 		return "[gray::i]No active memory segments"
 	}
 
@@ -339,7 +732,7 @@ func (m *model) renderSegments() string {
 
 	// Visual representation of segments
 	var row string
-	for i, s := range m.segments {
+	for i, s := range segments {
 		if i > 0 && i%8 == 0 {
 			result += row + "\n"
 			row = ""
@@ -386,9 +779,9 @@ func (m *model) renderSegments() string {
 	result += "\n[aqua::b]ID | Size | Status[white]\n"
 	result += "[white]------------------[white]\n"
 
-	for i, s := range m.segments {
-		if i >= 8 && len(m.segments) > 9 {
-			result += fmt.Sprintf("[gray]... and %d more[white]", len(m.segments)-8)
+	for i, s := range segments {
+		if i >= 8 && len(segments) > 9 {
+			result += fmt.Sprintf("[gray]... and %d more[white]", len(segments)-8)
 			break
 		}
 
@@ -421,18 +814,33 @@ func (m *model) renderSegments() string {
 
 // Render operations stats
 func (m *model) renderOperations() string {
+	m.mu.Lock()
+	totalAllocations := m.stats.totalAllocations
+	totalDeallocations := m.stats.totalDeallocations
+	compactedPages := m.compactedPages
+	swapOutCount := m.swapOutCount
+	swapInsPerSecond := m.swapInsPerSecond
+	m.mu.Unlock()
+
 	return fmt.Sprintf(
 		"\n[yellow]Allocations:[white] %d\n"+
 			"[yellow]Deallocations:[white] %d\n"+
+			"[yellow]Compacted:[white] %d pages\n"+
+			"[yellow]Swapped Out:[white] %d pages\n"+
+			"[yellow]Swap-ins/sec:[white] %.1f\n"+
 			"[yellow]Time:[white] %s",
-		m.stats.totalAllocations,
-		m.stats.totalDeallocations,
+		totalAllocations,
+		totalDeallocations,
+		compactedPages,
+		swapOutCount,
+		swapInsPerSecond,
 		m.stats.currentTime.Format("15:04:05"),
 	)
 }
 
 // Render memory bar
 func (m *model) renderMemoryBar() string {
+	m.mu.Lock()
 	// Calculate memory usage for pages
 	usedPages := 0
 	for _, p := range m.pages {
@@ -440,7 +848,9 @@ func (m *model) renderMemoryBar() string {
 			usedPages++
 		}
 	}
-	memoryUsage := float64(usedPages) / float64(len(m.pages)) * 100
+	pageCount := len(m.pages)
+	m.mu.Unlock()
+	memoryUsage := float64(usedPages) / float64(pageCount) * 100
 
 	width := 100
 	filled := int(memoryUsage * float64(width) / 100)
@@ -470,19 +880,30 @@ func (m *model) renderPages() string {
 	var result string
 	result = "[yellow]Fixed-size memory blocks (4KB each)[white]\n\n"
 
-	// Create rows of pages with more per row and vibrant colors
+	// Create rows of pages with more per row and vibrant colors. Migrating
+	// pages are drawn at an interpolated slot (see pageDisplaySlots) so they
+	// visibly slide toward their destination across several frames instead
+	// of snapping straight there.
 	var row string
-	for i, p := range m.pages {
+	for i, slot := range m.pageDisplaySlots() {
 		if i > 0 && i%16 == 0 {
 			result += row + "\n"
 			row = ""
 		}
 
-		if p.used {
+		if slot.migrating {
+			// Flash between two colors each redraw so the sliding page
+			// still reads as mid-migration, not just another block.
+			if m.animTick%2 == 0 {
+				row += "[yellow]▶ "
+			} else {
+				row += "[red]▶ "
+			}
+		} else if slot.used {
 			// Use different colors for different process IDs
 			processNum := 0
-			if len(p.processID) > 1 {
-				processNum = int(p.processID[1] - '0')
+			if len(slot.processID) > 1 {
+				processNum = int(slot.processID[1] - '0')
 			}
 
 			var blockColor string
@@ -510,80 +931,468 @@ func (m *model) renderPages() string {
 	}
 
 	// Add legend
-	result += "\n[green]■[white] allocated page  [gray]□[white] free page"
+	result += "\n[green]■[white] allocated page  [gray]□[white] free page  [yellow]▶[white] migrating (compaction)"
 
 	return result
 }
 
 // Render status bar
 func (m *model) renderStatusBar() string {
-	return "[green]a[white]:allocate | [green]d[white]:deallocate | [green]q[white]:quit"
+	if m.traceActive {
+		state := "playing"
+		if m.tracePaused {
+			state = "paused"
+		}
+		return fmt.Sprintf(
+			"[green]p[white]:pause | [green]n[white]:step | [green]r[white]:resume | trace %s | [green]?[white]:help | [green]q[white]:quit",
+			state,
+		)
+	}
+	return "[green]a[white]:allocate | [green]dd[white]:deallocate | [green]1-5[white]:strategy | " +
+		"[green]c[white]:compact | [green]+/-[white]:zoom | [green]space[white]:pause | [green]?[white]:help | [green]q[white]:quit"
 }
 
-// Allocate memory with contiguous pages
-func (m *model) allocateMemory() {
-	pid := fmt.Sprintf("P%d", rand.Intn(100))
+// zoomHistory widens or narrows the history panel's time window by delta
+// samples, clamped between 10 samples and the ring's full capacity.
+func (m *model) zoomHistory(delta int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
 
-	// Try to find contiguous free pages
-	startIdx := -1
-	contiguousCount := 0
-	requiredPages := rand.Intn(3) + 1 // Allocate 1-3 pages at once
+	window := m.historyWindow + delta
+	if window < 10 {
+		window = 10
+	}
+	if cap := m.history.Capacity(); window > cap {
+		window = cap
+	}
+	m.historyWindow = window
+}
 
-	for i := range m.pages {
-		if !m.pages[i].used {
-			if startIdx == -1 {
-				startIdx = i
-			}
-			contiguousCount++
-			if contiguousCount >= requiredPages {
-				break
+// renderHistory plots the recent page usage, fragmentation, and system
+// memory usage trends as braille line charts with min/max/current
+// annotations.
+func (m *model) renderHistory() string {
+	m.mu.Lock()
+	window := m.historyWindow
+	paused := m.historyPaused
+	m.mu.Unlock()
+
+	samples := m.history.Recent(window)
+
+	extract := func(key string) []float64 {
+		values := make([]float64, len(samples))
+		for i, s := range samples {
+			values[i] = s.Metrics[key]
+		}
+		return values
+	}
+
+	sections := []struct {
+		label string
+		key   string
+	}{
+		{"Page Usage", metricPageUsage},
+		{"Fragmentation", metricFragmentation},
+		{"System Usage", metricSystemUsage},
+	}
+
+	const chartWidth, chartHeight = 24, 2
+
+	pauseTag := ""
+	if paused {
+		pauseTag = " [red](paused)[white]"
+	}
+
+	result := fmt.Sprintf("[yellow]Window:[white] %d/%d samples%s\n\n", len(samples), m.history.Capacity(), pauseTag)
+	for _, sec := range sections {
+		values := extract(sec.key)
+
+		min, max, current := 0.0, 0.0, 0.0
+		if len(values) > 0 {
+			min, max = values[0], values[0]
+			for _, v := range values {
+				if v < min {
+					min = v
+				}
+				if v > max {
+					max = v
+				}
 			}
-		} else {
-			startIdx = -1
-			contiguousCount = 0
+			current = values[len(values)-1]
+		}
+
+		result += fmt.Sprintf("[aqua]%s[white] min=%.1f max=%.1f cur=%.1f\n", sec.label, min, max, current)
+		result += "[lime]" + renderBrailleLine(values, chartWidth, chartHeight) + "[white]\n"
+	}
+	return result
+}
+
+// renderStrategyStats shows success/failure counts and search-cost averages
+// for every allocation strategy, with the active one marked. Strategies are
+// compared sequentially, not side by side: switching strategies resets the
+// pool, so each row's stats come from that strategy's own clean run rather
+// than from a shared, simultaneous pool state.
+func (m *model) renderStrategyStats() string {
+	result := "[gray::i]sequential comparison (switching resets the pool)[white]\n"
+	for i, a := range m.allocators {
+		name := a.Name()
+		s := m.allocatorStats[name]
+
+		marker := "  "
+		if i == m.currentAllocator {
+			marker = "[lime]>[white] "
 		}
+
+		result += fmt.Sprintf(
+			"%s[yellow]%d:%s[white] ok=%d fail=%d steps=%.1f frag=%.1f%% int=%.1f\n",
+			marker, i+1, name, s.successes, s.failures,
+			s.averageSearchSteps(), s.averageFragmentation(), s.averageInternalFragmentation(),
+		)
 	}
+	return result
+}
+
+// selectAllocator switches the active allocation strategy. The page pool and
+// segment list are reset so each strategy is benchmarked against a clean
+// pool rather than one shaped by whatever strategy ran before it. This
+// makes the strategy panel a sequential comparison (one strategy's run
+// against another's, each on its own clean pool) rather than two strategies
+// examined side by side against the same live state.
+func (m *model) selectAllocator(index int) {
+	if index < 0 || index >= len(m.allocators) {
+		return
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.currentAllocator = index
+	m.pages = make([]page, len(m.pages))
+	m.segments = []segment{}
+	m.allocators[index] = resetAllocator(m.allocators[index], len(m.pages))
+	m.clockHand = 0
+	m.pendingMoves = nil
+	m.pendingSegmentStarts = nil
+	m.compactionFrame = 0
+}
 
-	// Allocate contiguous pages if found
-	if startIdx != -1 && contiguousCount >= requiredPages {
-		for i := startIdx; i < startIdx+requiredPages; i++ {
-			m.pages[i] = page{true, pid}
+// resetAllocator returns a fresh instance of the same strategy so its
+// internal bookkeeping (e.g. the buddy allocator's free lists) matches the
+// freshly cleared page pool.
+func resetAllocator(a Allocator, poolSize int) Allocator {
+	for _, fresh := range defaultAllocators(poolSize) {
+		if fresh.Name() == a.Name() {
+			return fresh
 		}
-		m.segments = append(m.segments, segment{requiredPages * int(m.stats.pageSize), pid})
-		m.stats.totalAllocations++
 	}
+	return a
+}
+
+// Allocate memory with contiguous pages, using whichever strategy is
+// currently selected via the '1'-'5' keybindings.
+func (m *model) allocateMemory() {
+	pid := fmt.Sprintf("P%d", rand.Intn(100))
+	requestedPages := rand.Intn(3) + 1 // Allocate 1-3 pages at once
+	m.allocate(pid, requestedPages)
+}
+
+// allocate requests requestedPages pages for pid through the active
+// strategy, recording the result in that strategy's stats. It reports
+// whether the allocation succeeded.
+//
+// If the strategy can't find room, this falls back to the page-replacement
+// subsystem: it evicts segments (via the active replacement policy) until
+// at least requestedPages pages have been evicted, then retries once.
+//
+// Refused outright while a compaction is in flight: pendingSegmentStarts is
+// keyed to the segment list as it stood when compact() staged the move, and
+// a new segment appended mid-slide would desync that indexing (or run past
+// the end of it) when advanceCompaction commits.
+func (m *model) allocate(pid string, requestedPages int) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if len(m.pendingMoves) > 0 {
+		return false
+	}
+
+	if m.tryAllocate(pid, requestedPages) {
+		return true
+	}
+
+	if m.evictUntilFit(requestedPages) == 0 {
+		return false
+	}
+	if !m.tryAllocate(pid, requestedPages) {
+		return false
+	}
+	m.swapInCount += requestedPages
+	m.swapInWindowCount += requestedPages
+	return true
+}
+
+// tryAllocate is the allocation attempt itself, with no eviction fallback.
+// Callers must hold mu.
+func (m *model) tryAllocate(pid string, requestedPages int) bool {
+	requestedSize := requestedPages * int(m.stats.pageSize)
+
+	allocator := m.activeAllocator()
+	stats := m.allocatorStats[allocator.Name()]
+
+	start, ok := allocator.Allocate(m.pages, requestedSize, pid)
+	if !ok {
+		stats.recordFailure(allocator.Steps())
+		return false
+	}
+
+	allocatedPages := requestedPages + allocator.InternalFragmentation()
+	m.segments = append(m.segments, segment{
+		size:       allocatedPages * int(m.stats.pageSize),
+		processID:  pid,
+		start:      start,
+		pageCount:  allocatedPages,
+		lastAccess: time.Now(),
+		referenced: true,
+	})
+	m.stats.totalAllocations++
+	stats.recordSuccess(allocator.Steps(), m.stats.fragmentationRate, allocator.InternalFragmentation())
+	return true
 }
 
-// Deallocate memory
+// Deallocate memory, freeing the most recently allocated segment through
+// the strategy that allocated it. Refused while a compaction is in flight
+// (see allocate's doc comment); freeing a segment mid-slide would shift the
+// indices advanceCompaction's pendingSegmentStarts was staged against.
 func (m *model) deallocateMemory() {
-	if len(m.segments) > 0 {
-		// Get the process ID of the last segment
-		lastPID := m.segments[len(m.segments)-1].processID
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if len(m.pendingMoves) > 0 || len(m.segments) == 0 {
+		return
+	}
+	m.deallocateAt(len(m.segments) - 1)
+}
+
+// deallocateByPID frees the most recent segment belonging to pid. It
+// reports whether a matching segment was found. Refused while a compaction
+// is in flight; see deallocateMemory.
+func (m *model) deallocateByPID(pid string) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if len(m.pendingMoves) > 0 {
+		return false
+	}
+
+	for i := len(m.segments) - 1; i >= 0; i-- {
+		if m.segments[i].processID == pid {
+			m.deallocateAt(i)
+			return true
+		}
+	}
+	return false
+}
+
+// deallocateAt frees the segment at index through the strategy that
+// allocated it. Callers must hold mu.
+func (m *model) deallocateAt(index int) {
+	target := m.segments[index]
+	m.segments = append(m.segments[:index], m.segments[index+1:]...)
 
-		// Remove the segment
-		m.segments = m.segments[:len(m.segments)-1]
+	m.activeAllocator().Free(m.pages, target.start, target.pageCount)
+	m.stats.totalDeallocations++
+}
+
+// traceWake unblocks runTrace if it's currently waiting out a pause,
+// without blocking the caller (the UI goroutine) if no one's listening.
+func (m *model) traceWake() {
+	select {
+	case m.traceControl <- struct{}{}:
+	default:
+	}
+}
+
+// runTrace drives allocate/deallocateByPID deterministically from a parsed
+// trace instead of the random keyboard-driven flow, so allocator strategies
+// can be compared against a fixed workload. It's meant to run in its own
+// goroutine; 'p'/'n'/'r' pause, single-step, and resume it via
+// traceControl. The model calls it makes (allocate, deallocateByPID,
+// writeSnapshot) all take m.mu themselves, so this goroutine's mutations
+// stay consistent with the render ticker and the key-handler goroutine
+// instead of racing them.
+func (m *model) runTrace(commands []workload.Command, snapshotDir string) {
+	for _, cmd := range commands {
+		if m.tracePaused {
+			<-m.traceControl
+		}
 
-		// Free pages with matching process ID
-		for i := range m.pages {
-			if m.pages[i].processID == lastPID {
-				m.pages[i] = page{false, ""}
+		switch cmd.Kind {
+		case workload.Alloc:
+			m.allocate(cmd.PID, cmd.Pages)
+		case workload.Free:
+			m.deallocateByPID(cmd.PID)
+		case workload.Sleep:
+			time.Sleep(cmd.Duration)
+		case workload.Snapshot:
+			if err := m.writeSnapshot(snapshotDir, cmd.Label); err != nil {
+				log.Printf("trace: %v", err)
 			}
 		}
+	}
+}
+
+// writeSnapshot dumps the current pages, segments, and stats to
+// <dir>/<label>.json, for comparing allocator strategies against the same
+// point in a trace.
+func (m *model) writeSnapshot(dir string, label string) error {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return fmt.Errorf("snapshot: creating %s: %w", dir, err)
+	}
+
+	type pageState struct {
+		Used      bool   `json:"used"`
+		ProcessID string `json:"process_id,omitempty"`
+	}
+	type segmentState struct {
+		ProcessID string `json:"process_id"`
+		SizeKB    int    `json:"size_kb"`
+		Start     int    `json:"start"`
+		PageCount int    `json:"page_count"`
+	}
+	type statsState struct {
+		TotalAllocations   int     `json:"total_allocations"`
+		TotalDeallocations int     `json:"total_deallocations"`
+		FragmentationRate  float64 `json:"fragmentation_rate"`
+		PeakMemoryUsage    float64 `json:"peak_memory_usage"`
+	}
+	type snapshot struct {
+		Label    string         `json:"label"`
+		Strategy string         `json:"strategy"`
+		Pages    []pageState    `json:"pages"`
+		Segments []segmentState `json:"segments"`
+		Stats    statsState     `json:"stats"`
+	}
+
+	m.mu.Lock()
+	snap := snapshot{
+		Label:    label,
+		Strategy: m.activeAllocator().Name(),
+		Pages:    make([]pageState, len(m.pages)),
+		Segments: make([]segmentState, len(m.segments)),
+		Stats: statsState{
+			TotalAllocations:   m.stats.totalAllocations,
+			TotalDeallocations: m.stats.totalDeallocations,
+			FragmentationRate:  m.stats.fragmentationRate,
+			PeakMemoryUsage:    m.stats.peakMemoryUsage,
+		},
+	}
+	for i, p := range m.pages {
+		snap.Pages[i] = pageState{Used: p.used, ProcessID: p.processID}
+	}
+	for i, s := range m.segments {
+		snap.Segments[i] = segmentState{
+			ProcessID: s.processID,
+			SizeKB:    s.size,
+			Start:     s.start,
+			PageCount: s.pageCount,
+		}
+	}
+	m.mu.Unlock()
+
+	data, err := json.MarshalIndent(snap, "", "  ")
+	if err != nil {
+		return fmt.Errorf("snapshot: marshaling %s: %w", label, err)
+	}
+
+	path := filepath.Join(dir, label+".json")
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		return fmt.Errorf("snapshot: writing %s: %w", path, err)
+	}
+	return nil
+}
+
+// intsOrDefault returns values if non-empty, otherwise fallback. Used so a
+// config file that omits layout.rows/layout.columns still gets the
+// original proportions instead of a degenerate zero-row grid.
+func intsOrDefault(values []int, fallback ...int) []int {
+	if len(values) == 0 {
+		return fallback
+	}
+	return values
+}
 
-		m.stats.totalDeallocations++
+// newRootCmd builds the memtracker CLI: a single command that loads the
+// config file, applies any flag overrides, and launches the TUI.
+func newRootCmd() *cobra.Command {
+	var configPath string
+	var colorschemeName string
+	var hideSystemMemory bool
+	var hideSegmentation bool
+	var historySize int
+	var sampleInterval time.Duration
+	var tracePath string
+	var snapshotDir string
+	var replacementPolicy string
+
+	cmd := &cobra.Command{
+		Use:   "memtracker",
+		Short: "A terminal UI for visualizing memory allocation strategies",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg, err := config.Load(configPath)
+			if err != nil {
+				return err
+			}
+			if colorschemeName != "" {
+				cs, err := config.LoadColorscheme(colorschemeName)
+				if err != nil {
+					return err
+				}
+				cfg.Colorscheme = *cs
+			}
+			if hideSystemMemory {
+				cfg.Panels.ShowSystemMemory = false
+			}
+			if hideSegmentation {
+				cfg.Panels.ShowSegmentation = false
+			}
+			applyColorscheme(cfg.Colorscheme)
+
+			if replacementPolicy != "lru" && replacementPolicy != "clock" {
+				return fmt.Errorf("invalid --replacement-policy %q (want lru or clock)", replacementPolicy)
+			}
+
+			rand.Seed(time.Now().UnixNano())
+			m := initialModel(cfg, historySize, sampleInterval, replacementPolicy)
+			root := m.createLayout()
+
+			if tracePath != "" {
+				commands, err := workload.ParseFile(tracePath)
+				if err != nil {
+					return err
+				}
+				m.traceActive = true
+				m.traceControl = make(chan struct{}, 1)
+				go m.runTrace(commands, snapshotDir)
+			}
+
+			return m.app.SetRoot(root, true).EnableMouse(true).Run()
+		},
 	}
+
+	cmd.Flags().StringVar(&configPath, "config", config.DefaultPath(), "path to config.yaml")
+	cmd.Flags().StringVar(&colorschemeName, "colorscheme", "", "bundled colorscheme to use (default, solarized, monokai, matrix)")
+	cmd.Flags().BoolVar(&hideSystemMemory, "hide-system-memory", false, "hide the system memory panel")
+	cmd.Flags().BoolVar(&hideSegmentation, "hide-segmentation", false, "hide the segmentation panel")
+	cmd.Flags().IntVar(&historySize, "history-size", 300, "number of samples to retain in the history panel")
+	cmd.Flags().DurationVar(&sampleInterval, "sample-interval", 500*time.Millisecond, "delay between stat samples")
+	cmd.Flags().StringVar(&tracePath, "trace", "", "path to a workload trace file to replay instead of driving allocation by keyboard")
+	cmd.Flags().StringVar(&snapshotDir, "snapshot-dir", "snapshots", "directory SNAPSHOT trace commands write their JSON dumps to")
+	cmd.Flags().StringVar(&replacementPolicy, "replacement-policy", "lru", "page-replacement policy used when an allocation can't find a contiguous run (lru, clock)")
+
+	return cmd
 }
 
 func main() {
-	rand.Seed(time.Now().UnixNano())
-
-	m := initialModel()
-	
-	// Create the UI layout
-	grid := m.createLayout()
-	
-	// Start the application
-	if err := m.app.SetRoot(grid, true).EnableMouse(true).Run(); err != nil {
-		log.Fatal("Error running application:", err)
-	}
-}
\ No newline at end of file
+	if err := newRootCmd().Execute(); err != nil {
+		log.Fatal(err)
+	}
+}
@@ -0,0 +1,94 @@
+package main
+
+// tickAnimations advances per-frame state that isn't tied to a single
+// sampled metric: the compaction flash, and the swap-ins/sec rate shown in
+// the operations panel. It runs once per redraw tick, alongside
+// updateStats.
+func (m *model) tickAnimations() {
+	m.animTick++
+	m.advanceCompaction()
+
+	m.mu.Lock()
+	m.swapInsPerSecond = float64(m.swapInWindowCount) / m.sampleInterval.Seconds()
+	m.swapInWindowCount = 0
+	m.mu.Unlock()
+}
+
+// evictUntilFit frees segments, chosen by the active replacement policy,
+// until the evicted segments add up to at least requiredPages pages (or
+// there's nothing left to evict); pages that were already free don't count
+// toward that total. It returns the number of pages actually freed. Callers must hold
+// mu (it's only ever called from allocate, which already does).
+func (m *model) evictUntilFit(requiredPages int) int {
+	freed := 0
+	for freed < requiredPages {
+		victim := m.selectVictim()
+		if victim == -1 {
+			break
+		}
+		freedPages := m.segments[victim].pageCount
+		m.deallocateAt(victim)
+		freed += freedPages
+		m.swapOutCount += freedPages
+	}
+	return freed
+}
+
+// selectVictim picks the segment index to evict next under the configured
+// replacement policy.
+func (m *model) selectVictim() int {
+	if len(m.segments) == 0 {
+		return -1
+	}
+	if m.replacementPolicy == "clock" {
+		return m.selectVictimClock()
+	}
+	return m.selectVictimLRU()
+}
+
+// selectVictimLRU evicts the segment that was allocated longest ago.
+//
+// lastAccess is stamped once, at allocation time, and nothing in this sim
+// ever touches a segment again after that, so this is really FIFO-by-
+// allocation-order rather than true least-recently-used; there's no access
+// event to refresh it against.
+func (m *model) selectVictimLRU() int {
+	oldest := 0
+	for i, s := range m.segments {
+		if s.lastAccess.Before(m.segments[oldest].lastAccess) {
+			oldest = i
+		}
+	}
+	return oldest
+}
+
+// selectVictimClock implements the second-chance clock algorithm: it sweeps
+// a hand across the segments, clearing the referenced bit on each one it
+// passes, and evicts the first segment it finds with the bit already
+// clear.
+//
+// referenced is set true only when a segment is first allocated and is
+// never set again afterward, so every segment gets exactly one second
+// chance from its own allocation rather than from any ongoing access
+// pattern; there's no access event in this sim to re-arm it.
+func (m *model) selectVictimClock() int {
+	n := len(m.segments)
+	if m.clockHand >= n {
+		m.clockHand = 0
+	}
+	for tries := 0; tries < 2*n; tries++ {
+		s := &m.segments[m.clockHand]
+		if !s.referenced {
+			victim := m.clockHand
+			m.clockHand = (m.clockHand + 1) % n
+			return victim
+		}
+		s.referenced = false
+		m.clockHand = (m.clockHand + 1) % n
+	}
+	// Every segment was referenced on this full sweep; evict wherever the
+	// hand now sits.
+	victim := m.clockHand
+	m.clockHand = (m.clockHand + 1) % n
+	return victim
+}
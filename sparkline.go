@@ -0,0 +1,110 @@
+package main
+
+import "strings"
+
+// brailleDotBits maps a dot's position within a character cell (2 columns
+// by 4 rows) to its bit in the Unicode braille block, following the
+// standard braille dot numbering (1-2-3-7 down the left column, 4-5-6-8
+// down the right).
+var brailleDotBits = [4][2]int{
+	{0x01, 0x08},
+	{0x02, 0x10},
+	{0x04, 0x20},
+	{0x40, 0x80},
+}
+
+// renderBrailleLine draws values as a line chart using braille characters,
+// packing a 2x4 sub-pixel grid into every width x height character cell.
+// Values are linearly resampled to fit the available horizontal resolution
+// and scaled to the vertical resolution using their own min/max.
+func renderBrailleLine(values []float64, width int, height int) string {
+	if width < 1 {
+		width = 1
+	}
+	if height < 1 {
+		height = 1
+	}
+	if len(values) == 0 {
+		return strings.Repeat(strings.Repeat(" ", width)+"\n", height)
+	}
+
+	cols := width * 2
+	rows := height * 4
+
+	min, max := values[0], values[0]
+	for _, v := range values {
+		if v < min {
+			min = v
+		}
+		if v > max {
+			max = v
+		}
+	}
+	if max == min {
+		max = min + 1
+	}
+
+	// Resample values onto the sub-pixel columns and convert each to a row.
+	rowAt := make([]int, cols)
+	for x := 0; x < cols; x++ {
+		srcPos := 0.0
+		if cols > 1 {
+			srcPos = float64(x) / float64(cols-1) * float64(len(values)-1)
+		}
+		lo := int(srcPos)
+		hi := lo + 1
+		if hi >= len(values) {
+			hi = len(values) - 1
+		}
+		frac := srcPos - float64(lo)
+		v := values[lo]*(1-frac) + values[hi]*frac
+
+		norm := (v - min) / (max - min)
+		row := rows - 1 - int(norm*float64(rows-1))
+		if row < 0 {
+			row = 0
+		}
+		if row >= rows {
+			row = rows - 1
+		}
+		rowAt[x] = row
+	}
+
+	dots := make([][]bool, rows)
+	for i := range dots {
+		dots[i] = make([]bool, cols)
+	}
+	for x := 0; x < cols; x++ {
+		y := rowAt[x]
+		dots[y][x] = true
+		if x > 0 {
+			// Fill the gap between consecutive columns so the line reads
+			// as continuous rather than a scatter of isolated points.
+			lo, hi := rowAt[x-1], y
+			if lo > hi {
+				lo, hi = hi, lo
+			}
+			for yy := lo; yy <= hi; yy++ {
+				dots[yy][x] = true
+			}
+		}
+	}
+
+	var b strings.Builder
+	for cy := 0; cy < height; cy++ {
+		for cx := 0; cx < width; cx++ {
+			bits := 0
+			for dy := 0; dy < 4; dy++ {
+				for dx := 0; dx < 2; dx++ {
+					row, col := cy*4+dy, cx*2+dx
+					if row < rows && col < cols && dots[row][col] {
+						bits |= brailleDotBits[dy][dx]
+					}
+				}
+			}
+			b.WriteRune(rune(0x2800 + bits))
+		}
+		b.WriteByte('\n')
+	}
+	return b.String()
+}
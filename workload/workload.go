@@ -0,0 +1,112 @@
+// Package workload parses trace files that drive the memory tracker
+// deterministically instead of through random keyboard-triggered
+// allocations, so allocator strategies can be compared against a fixed
+// sequence of events.
+package workload
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Kind identifies which of the four trace line formats a Command came
+// from.
+type Kind int
+
+const (
+	Alloc Kind = iota
+	Free
+	Sleep
+	Snapshot
+)
+
+// Command is one line of a trace: `ALLOC <pid> <pages>`, `FREE <pid>`,
+// `SLEEP <ms>`, or `SNAPSHOT <label>`.
+type Command struct {
+	Kind     Kind
+	PID      string
+	Pages    int
+	Duration time.Duration
+	Label    string
+}
+
+// ParseFile reads and parses the trace file at path.
+func ParseFile(path string) ([]Command, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("workload: opening %s: %w", path, err)
+	}
+	defer f.Close()
+	return Parse(f)
+}
+
+// Parse reads a trace from r. Blank lines and lines starting with '#' are
+// ignored.
+func Parse(r io.Reader) ([]Command, error) {
+	var commands []Command
+
+	scanner := bufio.NewScanner(r)
+	lineNum := 0
+	for scanner.Scan() {
+		lineNum++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		fields := strings.Fields(line)
+		cmd, err := parseLine(fields)
+		if err != nil {
+			return nil, fmt.Errorf("workload: line %d: %w", lineNum, err)
+		}
+		commands = append(commands, cmd)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("workload: reading trace: %w", err)
+	}
+	return commands, nil
+}
+
+func parseLine(fields []string) (Command, error) {
+	switch strings.ToUpper(fields[0]) {
+	case "ALLOC":
+		if len(fields) != 3 {
+			return Command{}, fmt.Errorf("ALLOC needs <pid> <pages>, got %q", strings.Join(fields, " "))
+		}
+		pages, err := strconv.Atoi(fields[2])
+		if err != nil {
+			return Command{}, fmt.Errorf("invalid page count %q", fields[2])
+		}
+		return Command{Kind: Alloc, PID: fields[1], Pages: pages}, nil
+
+	case "FREE":
+		if len(fields) != 2 {
+			return Command{}, fmt.Errorf("FREE needs <pid>, got %q", strings.Join(fields, " "))
+		}
+		return Command{Kind: Free, PID: fields[1]}, nil
+
+	case "SLEEP":
+		if len(fields) != 2 {
+			return Command{}, fmt.Errorf("SLEEP needs <ms>, got %q", strings.Join(fields, " "))
+		}
+		ms, err := strconv.Atoi(fields[1])
+		if err != nil {
+			return Command{}, fmt.Errorf("invalid duration %q", fields[1])
+		}
+		return Command{Kind: Sleep, Duration: time.Duration(ms) * time.Millisecond}, nil
+
+	case "SNAPSHOT":
+		if len(fields) != 2 {
+			return Command{}, fmt.Errorf("SNAPSHOT needs <label>, got %q", strings.Join(fields, " "))
+		}
+		return Command{Kind: Snapshot, Label: fields[1]}, nil
+
+	default:
+		return Command{}, fmt.Errorf("unknown command %q", fields[0])
+	}
+}